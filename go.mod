@@ -15,4 +15,5 @@ require (
 	github.com/temoto/robotstxt v1.1.2
 	github.com/unstppbl/gowap v0.0.0-20220824080738-254f64df4d44
 	go.zoe.im/surferua v0.0.3
+	golang.org/x/net v0.5.0
 )
@@ -0,0 +1,365 @@
+package scraper
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/net/dns/dnsmessage"
+	"golang.org/x/net/publicsuffix"
+)
+
+// DefaultDNSRecordTypes are the DNS records scraped when a scraper has no DNSRecordTypes
+var DefaultDNSRecordTypes = []string{"A", "AAAA", "CNAME", "MX", "NS", "SOA", "TXT"}
+
+// resolver looks up the DNS records of scrapeDNS
+type resolver interface {
+	LookupIPAddr(ctx context.Context, host string) ([]net.IPAddr, error)
+	LookupCNAME(ctx context.Context, host string) (string, error)
+	LookupMX(ctx context.Context, name string) ([]*net.MX, error)
+	LookupNS(ctx context.Context, name string) ([]*net.NS, error)
+	LookupTXT(ctx context.Context, name string) ([]string, error)
+	LookupSOA(ctx context.Context, name string) ([]string, error)
+	LookupAddr(ctx context.Context, addr string) ([]string, error)
+}
+
+// dnsResolver is replaced by a stub in tests
+var dnsResolver resolver = netResolver{net.DefaultResolver}
+
+// dnsOptions configure scrapeDNS
+type dnsOptions struct {
+	// recordTypes scraped, DefaultDNSRecordTypes when empty
+	recordTypes []string
+	// timeout of each lookup, none when 0
+	timeout time.Duration
+	// cache keeps the records of the scraper, they aren't cached when nil
+	cache *dnsCache
+	// tracer gets the span of the lookups, when not nil
+	tracer Tracer
+}
+
+// scrapeDNS looks up the record types of options, keyed by upper case type. A, AAAA and CNAME
+// are the ones of the host, the others of its registered domain. Lookup failures are skipped.
+func scrapeDNS(ctx context.Context, paramURL string, options dnsOptions) map[string][]string {
+	ctx, span := startSpan(ctx, options.tracer, "scraper.dns", paramURL)
+	defer span.End(nil)
+	scrapedDNS := make(map[string][]string)
+	u, err := url.Parse(paramURL)
+	if err != nil || net.ParseIP(u.Hostname()) != nil {
+		return scrapedDNS
+	}
+	host := strings.TrimSuffix(u.Hostname(), ".")
+	// Single label hosts like localhost have no public DNS records
+	if !strings.Contains(host, ".") {
+		return scrapedDNS
+	}
+	// The zone is the registered domain, ex: example.co.uk for www.example.co.uk
+	domain, err := publicsuffix.EffectiveTLDPlusOne(host)
+	if err != nil {
+		domain = host
+	}
+	recordTypes := options.recordTypes
+	if len(recordTypes) == 0 {
+		recordTypes = DefaultDNSRecordTypes
+	}
+
+	for _, recordType := range recordTypes {
+		recordType = strings.ToUpper(recordType)
+		name := domain
+		if recordType == "A" || recordType == "AAAA" || recordType == "CNAME" {
+			name = host
+		}
+		records, ok := options.cache.get(name, recordType)
+		if !ok {
+			lookupCtx, cancel := ctx, context.CancelFunc(func() {})
+			if options.timeout > 0 {
+				lookupCtx, cancel = context.WithTimeout(ctx, options.timeout)
+			}
+			records, err = lookupDNS(lookupCtx, name, recordType)
+			cancel()
+			if err != nil {
+				continue
+			}
+			options.cache.set(name, recordType, records)
+		}
+		if len(records) > 0 {
+			scrapedDNS[recordType] = append(scrapedDNS[recordType], records...)
+		}
+	}
+
+	return scrapedDNS
+}
+
+// resolveIPs returns the IP addresses the host of paramURL resolves to, IPv4 first, with the
+// timeout and the cache of options. An IP host is returned as is, nil when it can't be resolved.
+func resolveIPs(ctx context.Context, paramURL string, options dnsOptions) []string {
+	u, err := url.Parse(paramURL)
+	if err != nil || u.Hostname() == "" {
+		return nil
+	}
+	if ip := net.ParseIP(u.Hostname()); ip != nil {
+		return []string{ip.String()}
+	}
+	host := strings.TrimSuffix(u.Hostname(), ".")
+	if ips, ok := options.cache.get(host, "IP"); ok {
+		return ips
+	}
+	lookupCtx, cancel := ctx, context.CancelFunc(func() {})
+	if options.timeout > 0 {
+		lookupCtx, cancel = context.WithTimeout(ctx, options.timeout)
+	}
+	defer cancel()
+	addrs, err := dnsResolver.LookupIPAddr(lookupCtx, host)
+	if err != nil {
+		return nil
+	}
+	var ipv4, ipv6 []string
+	for _, addr := range addrs {
+		ip := addr.IP.String()
+		if addr.IP.To4() != nil && !contains(ipv4, ip) {
+			ipv4 = append(ipv4, ip)
+		} else if addr.IP.To4() == nil && !contains(ipv6, ip) {
+			ipv6 = append(ipv6, ip)
+		}
+	}
+	ips := append(ipv4, ipv6...)
+	options.cache.set(host, "IP", ips)
+	return ips
+}
+
+// reverseDNS looks up the PTR records of ips, keyed by IP, with the timeout and the cache of
+// options. The IPs without PTR record or whose lookup failed are skipped.
+func reverseDNS(ctx context.Context, ips []string, options dnsOptions) map[string][]string {
+	names := make(map[string][]string)
+	for _, ip := range ips {
+		records, ok := options.cache.get(ip, "PTR")
+		if !ok {
+			lookupCtx, cancel := ctx, context.CancelFunc(func() {})
+			if options.timeout > 0 {
+				lookupCtx, cancel = context.WithTimeout(ctx, options.timeout)
+			}
+			var err error
+			records, err = dnsResolver.LookupAddr(lookupCtx, ip)
+			cancel()
+			if err != nil {
+				continue
+			}
+			options.cache.set(ip, "PTR", records)
+		}
+		if len(records) > 0 {
+			names[ip] = records
+		}
+	}
+	return names
+}
+
+// lookupDNS returns the recordType records of name
+func lookupDNS(ctx context.Context, name string, recordType string) (records []string, err error) {
+	switch recordType {
+	case "A", "AAAA":
+		ips, err := dnsResolver.LookupIPAddr(ctx, name)
+		for _, ip := range ips {
+			if (ip.IP.To4() != nil) == (recordType == "A") {
+				records = append(records, ip.IP.String())
+			}
+		}
+		return records, err
+	case "CNAME":
+		// Without CNAME the name itself is returned
+		cname, err := dnsResolver.LookupCNAME(ctx, name)
+		if cname != "" && strings.TrimSuffix(cname, ".") != name {
+			records = append(records, cname)
+		}
+		return records, err
+	case "MX":
+		mxSlice, err := dnsResolver.LookupMX(ctx, name)
+		for _, mx := range mxSlice {
+			records = append(records, mx.Host)
+		}
+		return records, err
+	case "NS":
+		nsSlice, err := dnsResolver.LookupNS(ctx, name)
+		for _, ns := range nsSlice {
+			records = append(records, ns.Host)
+		}
+		return records, err
+	case "SOA":
+		return dnsResolver.LookupSOA(ctx, name)
+	case "TXT":
+		return dnsResolver.LookupTXT(ctx, name)
+	}
+	return nil, fmt.Errorf("UnknownDNSRecordType %s", recordType)
+}
+
+// maxDNSCacheEntries caps the records kept by a dnsCache, the oldest ones are evicted first
+const maxDNSCacheEntries = 10000
+
+// dnsCache keeps the records looked up by a scraper, by name and record type, for ttl
+type dnsCache struct {
+	lock    sync.RWMutex
+	ttl     time.Duration
+	entries map[string]dnsCacheEntry
+}
+
+type dnsCacheEntry struct {
+	records []string
+	fetched time.Time
+}
+
+// newDNSCache returns a cache keeping the records for ttl, nil when ttl is 0
+func newDNSCache(ttl time.Duration) *dnsCache {
+	if ttl <= 0 {
+		return nil
+	}
+	return &dnsCache{ttl: ttl, entries: make(map[string]dnsCacheEntry)}
+}
+
+// get returns the records of name fetched less than ttl ago, a nil cache has none
+func (c *dnsCache) get(name string, recordType string) ([]string, bool) {
+	if c == nil {
+		return nil, false
+	}
+	c.lock.RLock()
+	entry, ok := c.entries[recordType+" "+name]
+	c.lock.RUnlock()
+	if !ok || time.Since(entry.fetched) > c.ttl {
+		return nil, false
+	}
+	return entry.records, true
+}
+
+// set keeps the records of name, evicting the expired records, then the oldest ones, when the cache is full
+func (c *dnsCache) set(name string, recordType string, records []string) {
+	if c == nil {
+		return
+	}
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	key := recordType + " " + name
+	if _, ok := c.entries[key]; !ok && len(c.entries) >= maxDNSCacheEntries {
+		c.evict()
+	}
+	c.entries[key] = dnsCacheEntry{records, time.Now()}
+}
+
+// evict removes the expired entries, or the oldest one when none has expired
+func (c *dnsCache) evict() {
+	var oldestKey string
+	var oldest time.Time
+	for key, entry := range c.entries {
+		if time.Since(entry.fetched) > c.ttl {
+			delete(c.entries, key)
+		} else if oldestKey == "" || entry.fetched.Before(oldest) {
+			oldestKey, oldest = key, entry.fetched
+		}
+	}
+	if len(c.entries) >= maxDNSCacheEntries {
+		delete(c.entries, oldestKey)
+	}
+}
+
+// netResolver adds the SOA lookup, missing from the net package, to a net.Resolver
+type netResolver struct {
+	*net.Resolver
+}
+
+// LookupSOA asks the SOA record of name to its first name server.
+// The record is formatted as in zone files: "mname rname serial refresh retry expire minimum".
+func (r netResolver) LookupSOA(ctx context.Context, name string) ([]string, error) {
+	nsSlice, err := r.LookupNS(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	if len(nsSlice) == 0 {
+		return nil, errors.New("NoNameServer")
+	}
+	var id [2]byte
+	if _, err := rand.Read(id[:]); err != nil {
+		return nil, err
+	}
+	query, err := soaQuery(binary.BigEndian.Uint16(id[:]), name)
+	if err != nil {
+		return nil, err
+	}
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "udp", net.JoinHostPort(strings.TrimSuffix(nsSlice[0].Host, "."), "53"))
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		deadline = time.Now().Add(5 * time.Second)
+	}
+	conn.SetDeadline(deadline) //nolint:errcheck
+
+	if _, err = conn.Write(query); err != nil {
+		return nil, err
+	}
+	response := make([]byte, 4096)
+	n, err := conn.Read(response)
+	if err != nil {
+		return nil, err
+	}
+	return parseSOAResponse(binary.BigEndian.Uint16(id[:]), name, response[:n])
+}
+
+// soaQuery builds the DNS message asking the SOA record of name
+func soaQuery(id uint16, name string) ([]byte, error) {
+	question, err := dnsmessage.NewName(strings.TrimSuffix(name, ".") + ".")
+	if err != nil {
+		return nil, err
+	}
+	msg := dnsmessage.Message{
+		Header:    dnsmessage.Header{ID: id},
+		Questions: []dnsmessage.Question{{Name: question, Type: dnsmessage.TypeSOA, Class: dnsmessage.ClassINET}},
+	}
+	return msg.Pack()
+}
+
+// parseSOAResponse returns the SOA records of the answers of a DNS response to the id query of name
+func parseSOAResponse(id uint16, name string, msg []byte) ([]string, error) {
+	var parser dnsmessage.Parser
+	header, err := parser.Start(msg)
+	if err != nil || header.ID != id {
+		return nil, errors.New("InvalidDNSResponse")
+	}
+	if header.RCode != dnsmessage.RCodeSuccess {
+		return nil, fmt.Errorf("DNSError%d", header.RCode)
+	}
+	question, err := parser.Question()
+	if err != nil || !strings.EqualFold(question.Name.String(), strings.TrimSuffix(name, ".")+".") || question.Type != dnsmessage.TypeSOA {
+		return nil, errors.New("InvalidDNSResponse")
+	}
+	if err := parser.SkipAllQuestions(); err != nil {
+		return nil, errors.New("InvalidDNSResponse")
+	}
+	var records []string
+	for {
+		answer, err := parser.AnswerHeader()
+		if err == dnsmessage.ErrSectionDone {
+			return records, nil
+		}
+		if err != nil {
+			return nil, errors.New("InvalidDNSResponse")
+		}
+		if answer.Type != dnsmessage.TypeSOA {
+			if err := parser.SkipAnswer(); err != nil {
+				return nil, errors.New("InvalidDNSResponse")
+			}
+			continue
+		}
+		soa, err := parser.SOAResource()
+		if err != nil {
+			return nil, errors.New("InvalidDNSResponse")
+		}
+		records = append(records, fmt.Sprintf("%s %s %d %d %d %d %d", soa.NS, soa.MBox, soa.Serial, soa.Refresh, soa.Retry, soa.Expire, soa.MinTTL))
+	}
+}
@@ -0,0 +1,118 @@
+package scraper
+
+import (
+	"context"
+	"strings"
+	"sync"
+)
+
+// MockScraper answers with canned data instead of loading the pages, ex: to test an integration
+// of gowap without network nor browser. Its zero value answers 404 to every URL.
+// Its fields are set before the scrapes, which can then run concurrently.
+type MockScraper struct {
+	// Pages are the data scraped on the URLs, a trailing slash doesn't matter
+	Pages map[string]*ScrapedData
+	// Data is scraped on the URLs missing from Pages, they answer 404 without it
+	Data *ScrapedData
+	// Err is returned by every scrape instead of the data
+	Err error
+	// JS are the values of the JS properties, the missing ones aren't defined
+	JS map[string]string
+	// DOM are the values of the DOM properties, indexed by selector then property
+	DOM map[string]map[string]string
+	// RenderPage tells the page is rendered, implied by JS and DOM: only the JS and DOM
+	// patterns of rendered pages are evaluated
+	RenderPage bool
+	// Scraped are the scraped URLs, in order. ScrapedURLs reads them while scrapes are running.
+	Scraped []string
+	mu      sync.Mutex
+}
+
+func (s *MockScraper) Init(url string) error {
+	return nil
+}
+
+func (s *MockScraper) CanRenderPage() bool {
+	return s.RenderPage || len(s.JS) > 0 || len(s.DOM) > 0
+}
+
+// SetDepth is a no-op, the mock has no robots.txt to respect
+func (s *MockScraper) SetDepth(depth int) {}
+
+// Concurrent tells the scrapes can run concurrently
+func (s *MockScraper) Concurrent() bool {
+	return true
+}
+
+// ScrapedURLs returns a copy of Scraped
+func (s *MockScraper) ScrapedURLs() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.Scraped...)
+}
+
+// Scrape returns a copy of the data of paramURL, its URL and status default to paramURL and 200
+func (s *MockScraper) Scrape(ctx context.Context, paramURL string) (*ScrapedData, error) {
+	s.mu.Lock()
+	s.Scraped = append(s.Scraped, paramURL)
+	s.mu.Unlock()
+	if err := ctx.Err(); err != nil {
+		return &ScrapedData{}, err
+	}
+	if s.Err != nil {
+		return &ScrapedData{}, s.Err
+	}
+	data := s.page(paramURL)
+	if data == nil {
+		return &ScrapedData{URLs: ScrapedURL{paramURL, 404}}, nil
+	}
+	scraped := *data
+	if scraped.URLs.URL == "" {
+		scraped.URLs.URL = paramURL
+	}
+	if scraped.URLs.Status == 0 {
+		scraped.URLs.Status = 200
+	}
+	return &scraped, nil
+}
+
+// page returns the data of paramURL, with or without trailing slash, else Data
+func (s *MockScraper) page(paramURL string) *ScrapedData {
+	trimmed := strings.TrimSuffix(paramURL, "/")
+	for _, u := range []string{paramURL, trimmed, trimmed + "/"} {
+		if data, ok := s.Pages[u]; ok {
+			return data
+		}
+	}
+	return s.Data
+}
+
+func (s *MockScraper) EvalJS(jsProp string) (*string, error) {
+	value, ok := s.JS[jsProp]
+	if !ok {
+		return nil, nil
+	}
+	return &value, nil
+}
+
+func (s *MockScraper) EvalJSBatch(jsProps []string) (map[string]*string, error) {
+	values := make(map[string]*string)
+	for _, jsProp := range jsProps {
+		if value, ok := s.JS[jsProp]; ok {
+			values[jsProp] = &value
+		}
+	}
+	return values, nil
+}
+
+func (s *MockScraper) EvalDomProperty(selector string, property string) (*string, error) {
+	value, ok := s.DOM[selector][property]
+	if !ok {
+		return nil, nil
+	}
+	return &value, nil
+}
+
+func (s *MockScraper) Close() error {
+	return nil
+}
@@ -0,0 +1,142 @@
+package scraper
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// ErrUnknownRotation is returned by RotatingScraper.Init when its Rotation isn't supported
+var ErrUnknownRotation = errors.New("UnknownRotation")
+
+// Rotations of the RotatingScraper
+const (
+	// RotationRoundRobin uses the scrapers one after the other
+	RotationRoundRobin = "round-robin"
+	// RotationRandom picks a random scraper for each scrape
+	RotationRandom = "random"
+)
+
+// RotatingScraper spreads the scrapes over Scrapers, ex: one per proxy to avoid bans.
+// The evaluations of a page go to the scraper which scraped it: its page handle is returned
+// with the scraped data, the EvalJS calls go to the scraper of the last scrape.
+// The scrapes can run concurrently.
+type RotatingScraper struct {
+	Scrapers []Scraper
+	// Rotation picks the scraper of each scrape, RotationRoundRobin when empty
+	Rotation string
+	// mu guards the rotation and the current scraper
+	mu      sync.Mutex
+	next    int
+	current Scraper
+	random  *rand.Rand
+}
+
+// Init initializes every scraper, Close releases them even when one failed
+func (s *RotatingScraper) Init(url string) error {
+	if len(s.Scrapers) == 0 {
+		return errors.New("NoScrapers")
+	}
+	switch s.Rotation {
+	case "", RotationRoundRobin:
+	case RotationRandom:
+		s.random = rand.New(rand.NewSource(time.Now().UnixNano()))
+	default:
+		return fmt.Errorf("%w: %s", ErrUnknownRotation, s.Rotation)
+	}
+	for _, scraper := range s.Scrapers {
+		if err := scraper.Init(url); err != nil {
+			return err
+		}
+	}
+	s.mu.Lock()
+	s.current = s.Scrapers[0]
+	s.mu.Unlock()
+	return nil
+}
+
+// CanRenderPage tells if the scrapers, all of the same kind, can render the pages
+func (s *RotatingScraper) CanRenderPage() bool {
+	return len(s.Scrapers) > 0 && s.Scrapers[0].CanRenderPage()
+}
+
+// Concurrent tells the scrapes can run concurrently, when they can with each scraper
+func (s *RotatingScraper) Concurrent() bool {
+	for _, scraper := range s.Scrapers {
+		if concurrent, ok := scraper.(ConcurrentScraper); !ok || !concurrent.Concurrent() {
+			return false
+		}
+	}
+	return len(s.Scrapers) > 0
+}
+
+func (s *RotatingScraper) SetDepth(depth int) {
+	for _, scraper := range s.Scrapers {
+		scraper.SetDepth(depth)
+	}
+}
+
+// Scrape scrapes paramURL with the next scraper of the rotation
+func (s *RotatingScraper) Scrape(ctx context.Context, paramURL string) (*ScrapedData, error) {
+	if len(s.Scrapers) == 0 {
+		return &ScrapedData{}, errors.New("NoScrapers")
+	}
+	s.mu.Lock()
+	if s.random != nil {
+		s.current = s.Scrapers[s.random.Intn(len(s.Scrapers))]
+	} else {
+		s.current = s.Scrapers[s.next%len(s.Scrapers)]
+		s.next++
+	}
+	scraper := s.current
+	s.mu.Unlock()
+	return scraper.Scrape(ctx, paramURL)
+}
+
+// currentScraper returns the scraper of the last scrape
+func (s *RotatingScraper) currentScraper() (Scraper, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.current == nil {
+		return nil, errors.New("NoPage")
+	}
+	return s.current, nil
+}
+
+func (s *RotatingScraper) EvalJS(jsProp string) (*string, error) {
+	scraper, err := s.currentScraper()
+	if err != nil {
+		return nil, err
+	}
+	return scraper.EvalJS(jsProp)
+}
+
+func (s *RotatingScraper) EvalJSBatch(jsProps []string) (map[string]*string, error) {
+	scraper, err := s.currentScraper()
+	if err != nil {
+		return nil, err
+	}
+	return scraper.EvalJSBatch(jsProps)
+}
+
+func (s *RotatingScraper) EvalDomProperty(selector string, property string) (*string, error) {
+	scraper, err := s.currentScraper()
+	if err != nil {
+		return nil, err
+	}
+	return scraper.EvalDomProperty(selector, property)
+}
+
+// Close closes every scraper and returns the first error
+func (s *RotatingScraper) Close() error {
+	var err error
+	for _, scraper := range s.Scrapers {
+		if closeErr := scraper.Close(); closeErr != nil && err == nil {
+			err = closeErr
+		}
+	}
+	return err
+}